@@ -0,0 +1,321 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"sync"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/juju/errors"
+	goctx "golang.org/x/net/context"
+)
+
+// ElectionDriver abstracts the coordination primitive backing owner
+// election, so ownerManager's campaign/resign/watch state machine works the
+// same whether leadership is decided across a cluster through etcd or,
+// for a standalone node, entirely in-process.
+type ElectionDriver interface {
+	// Campaign blocks until val wins the election for key, or ctx is done.
+	Campaign(ctx goctx.Context, key, val string) error
+	// Resign gives up leadership of key, if currently held.
+	Resign(ctx goctx.Context, key string) error
+	// Observe streams the current leader's value for key every time it
+	// changes; the channel is closed when ctx is done or there's no more
+	// leader information to report.
+	Observe(ctx goctx.Context, key string) <-chan string
+	// Session establishes the driver's underlying lease/session with the
+	// given TTL (in seconds) and returns a channel that's closed once it
+	// expires. Retrying a failed attempt is the caller's responsibility.
+	Session(ctx goctx.Context, ttl int) (<-chan struct{}, error)
+}
+
+// transferableDriver is implemented by ElectionDrivers that support
+// TransferOwner's out-of-band intent/ack protocol, and the per-candidate
+// priority announcements campaignLoop and watchOwner publish under
+// priorityKey(key): Notify publishes a value for other candidates to see,
+// WatchKey/WatchPrefix stream it back out, and Get/GetPrefix read whatever
+// was last published so a caller that starts watching late doesn't miss
+// it.
+type transferableDriver interface {
+	Notify(ctx goctx.Context, key, val string) error
+	WatchKey(ctx goctx.Context, key string) <-chan string
+	WatchPrefix(ctx goctx.Context, prefix string) <-chan string
+	Get(ctx goctx.Context, key string) (string, error)
+	GetPrefix(ctx goctx.Context, prefix string) (map[string]string, error)
+	// Delete removes key, so a caller can clean up a Notify'd key once
+	// it's no longer needed instead of leaving it behind indefinitely.
+	Delete(ctx goctx.Context, key string) error
+}
+
+// etcdDriver is the ElectionDriver backing a normal, multi-node TiDB
+// deployment: it's a thin wrapper around clientv3/concurrency.
+type etcdDriver struct {
+	cli *clientv3.Client
+
+	mu        sync.Mutex
+	session   *concurrency.Session
+	elections map[string]*concurrency.Election
+}
+
+func newEtcdDriver(cli *clientv3.Client) *etcdDriver {
+	return &etcdDriver{
+		cli:       cli,
+		elections: make(map[string]*concurrency.Election),
+	}
+}
+
+// Session implements ElectionDriver.Session interface.
+func (d *etcdDriver) Session(ctx goctx.Context, ttl int) (<-chan struct{}, error) {
+	s, err := concurrency.NewSession(d.cli, concurrency.WithTTL(ttl), concurrency.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	d.mu.Lock()
+	d.session = s
+	d.mu.Unlock()
+	return s.Done(), nil
+}
+
+// Campaign implements ElectionDriver.Campaign interface.
+func (d *etcdDriver) Campaign(ctx goctx.Context, key, val string) error {
+	d.mu.Lock()
+	s := d.session
+	d.mu.Unlock()
+
+	elec := concurrency.NewElection(s, key)
+	d.mu.Lock()
+	d.elections[key] = elec
+	d.mu.Unlock()
+
+	if err := elec.Campaign(ctx, val); err != nil {
+		return errors.Trace(err)
+	}
+
+	// Get owner information.
+	resp, err := elec.Leader(ctx)
+	if err != nil {
+		// If no leader elected currently, it returns ErrElectionNoLeader.
+		return errors.Trace(err)
+	}
+	if string(resp.Kvs[0].Value) != val {
+		return errors.Errorf("%s campaigned but %s is the owner of %s", val, resp.Kvs[0].Value, key)
+	}
+	return nil
+}
+
+// Resign implements ElectionDriver.Resign interface.
+func (d *etcdDriver) Resign(ctx goctx.Context, key string) error {
+	d.mu.Lock()
+	elec := d.elections[key]
+	d.mu.Unlock()
+	if elec == nil {
+		return nil
+	}
+	return errors.Trace(elec.Resign(ctx))
+}
+
+// Observe implements ElectionDriver.Observe interface. It works whether or
+// not this driver has campaigned for key yet, so a ReadOnly node that
+// never calls Campaign can still track who the current owner is.
+//
+// It deliberately doesn't use concurrency.Election.Observe: that only ever
+// delivers a fresh PUT from a new leader, so it never surfaces a DELETE
+// that leaves no candidate waiting (e.g. the last node resigning), and a
+// caller like watchOwner would block forever instead of noticing it lost
+// ownership. Instead it watches the current leader's own key directly, the
+// same key returned by Leader, so both PUT and DELETE are visible.
+func (d *etcdDriver) Observe(ctx goctx.Context, key string) <-chan string {
+	out := make(chan string)
+	d.mu.Lock()
+	elec := d.elections[key]
+	s := d.session
+	d.mu.Unlock()
+	if elec == nil {
+		elec = concurrency.NewElection(s, key)
+	}
+
+	go func() {
+		defer close(out)
+		resp, err := elec.Leader(ctx)
+		if err != nil || len(resp.Kvs) == 0 {
+			// If no leader elected currently, Leader returns
+			// ErrElectionNoLeader; there's nothing to observe yet.
+			return
+		}
+		select {
+		case out <- string(resp.Kvs[0].Value):
+		case <-ctx.Done():
+			return
+		}
+
+		watchCh := d.cli.Watch(ctx, string(resp.Kvs[0].Key))
+		for {
+			select {
+			case wresp, ok := <-watchCh:
+				if !ok || wresp.Canceled {
+					return
+				}
+				for _, ev := range wresp.Events {
+					if ev.Type == mvccpb.DELETE {
+						return
+					}
+					select {
+					case out <- string(ev.Kv.Value):
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Notify implements transferableDriver.Notify interface. The key is bound
+// to the current session's lease, so a node's announcements disappear once
+// its session expires instead of outliving the node.
+func (d *etcdDriver) Notify(ctx goctx.Context, key, val string) error {
+	d.mu.Lock()
+	s := d.session
+	d.mu.Unlock()
+	var opts []clientv3.OpOption
+	if s != nil {
+		opts = append(opts, clientv3.WithLease(s.Lease()))
+	}
+	_, err := d.cli.Put(ctx, key, val, opts...)
+	return errors.Trace(err)
+}
+
+// Delete implements transferableDriver.Delete interface.
+func (d *etcdDriver) Delete(ctx goctx.Context, key string) error {
+	_, err := d.cli.Delete(ctx, key)
+	return errors.Trace(err)
+}
+
+// Get implements transferableDriver.Get interface.
+func (d *etcdDriver) Get(ctx goctx.Context, key string) (string, error) {
+	resp, err := d.cli.Get(ctx, key)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(resp.Kvs) == 0 {
+		return "", nil
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+// GetPrefix implements transferableDriver.GetPrefix interface.
+func (d *etcdDriver) GetPrefix(ctx goctx.Context, prefix string) (map[string]string, error) {
+	resp, err := d.cli.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vals := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		vals[string(kv.Key)] = string(kv.Value)
+	}
+	return vals, nil
+}
+
+// watch streams the value of every PUT under key (or, with
+// clientv3.WithPrefix(), under the prefix key) until ctx is done.
+func (d *etcdDriver) watch(ctx goctx.Context, key string, opts ...clientv3.OpOption) <-chan string {
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		watchCh := d.cli.Watch(ctx, key, opts...)
+		for {
+			select {
+			case resp, ok := <-watchCh:
+				if !ok || resp.Canceled {
+					return
+				}
+				for _, ev := range resp.Events {
+					if ev.Type != mvccpb.PUT {
+						continue
+					}
+					select {
+					case out <- string(ev.Kv.Value):
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// WatchKey implements transferableDriver.WatchKey interface.
+func (d *etcdDriver) WatchKey(ctx goctx.Context, key string) <-chan string {
+	return d.watch(ctx, key)
+}
+
+// WatchPrefix implements transferableDriver.WatchPrefix interface.
+func (d *etcdDriver) WatchPrefix(ctx goctx.Context, prefix string) <-chan string {
+	return d.watch(ctx, prefix, clientv3.WithPrefix())
+}
+
+// localDriver is the ElectionDriver for standalone deployments with no
+// etcd cluster: it's an in-process singleton that always reports ddlID as
+// the winner of every key it's asked to campaign for, so a lone TiDB node
+// can still run DDL and background jobs without an external coordinator.
+type localDriver struct {
+	ddlID string
+}
+
+func newLocalDriver(ddlID string) *localDriver {
+	return &localDriver{ddlID: ddlID}
+}
+
+// Session is a no-op: there's no external lease to keep alive, so the
+// returned channel is only closed when ctx is done.
+func (d *localDriver) Session(ctx goctx.Context, ttl int) (<-chan struct{}, error) {
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(done)
+	}()
+	return done, nil
+}
+
+// Campaign always succeeds immediately: a standalone node is the only
+// candidate, so it's always the owner.
+func (d *localDriver) Campaign(ctx goctx.Context, key, val string) error {
+	return nil
+}
+
+// Resign is a no-op: with no other candidate to hand ownership to, a
+// standalone node can't meaningfully give up being the owner.
+func (d *localDriver) Resign(ctx goctx.Context, key string) error {
+	return nil
+}
+
+// Observe never reports a change of leadership: the local node is the
+// owner of every key for as long as the process runs.
+func (d *localDriver) Observe(ctx goctx.Context, key string) <-chan string {
+	ch := make(chan string)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch
+}
@@ -14,14 +14,14 @@
 package ddl
 
 import (
+	"encoding/json"
 	"math"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/coreos/etcd/clientv3"
-	"github.com/coreos/etcd/clientv3/concurrency"
-	"github.com/coreos/etcd/mvcc/mvccpb"
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	goctx "golang.org/x/net/context"
@@ -43,31 +43,308 @@ type OwnerManager interface {
 	CampaignOwners(ctx goctx.Context, wg *sync.WaitGroup) error
 	// Cancel cancels this etcd ownerManager campaign.
 	Cancel()
+	// ResignOwner lets the DDL owner resign voluntarily, without leaving the
+	// campaign loop, so this node re-enters the race for the key afterwards.
+	ResignOwner(ctx goctx.Context) error
+	// ResignBgOwner lets the background owner resign voluntarily, without
+	// leaving the campaign loop.
+	ResignBgOwner(ctx goctx.Context) error
+	// TransferOwner asks the DDL owner to hand leadership to the candidate
+	// with the given ddlID.
+	TransferOwner(ctx goctx.Context, targetID string) error
+	// Subscribe registers a listener that's invoked whenever this node's
+	// ownership of key flips, so callers can react to leadership changes
+	// instead of polling IsOwner/IsBgOwner in a hot path. Listeners run on a
+	// separate dispatch goroutine, not on the campaign loop, and an event is
+	// dropped if notifyChanSize events are already waiting to be dispatched.
+	Subscribe(listener OwnerChangeListener)
+	// Config returns the currently effective OwnerManagerConfig.
+	Config() OwnerManagerConfig
+	// SetConfig updates the effective OwnerManagerConfig. It takes effect
+	// on the next session/campaign retry, without requiring a restart.
+	SetConfig(cfg OwnerManagerConfig)
+	// Candidates returns every node currently campaigning for key,
+	// including the current owner, for a caller such as
+	// INFORMATION_SCHEMA.TIDB_SERVERS_INFO to display. It's empty, without
+	// error, when the driver doesn't support priority candidacy (e.g. the
+	// standalone localDriver).
+	Candidates(ctx goctx.Context, key string) ([]CandidateInfo, error)
 }
 
+// BackoffConfig parameterizes a decorrelated-jitter backoff: each sleep is
+// a random duration in [Base, min(Max, prev*Multiplier)). Spreading
+// retries out like this, instead of sleeping a fixed duration, keeps a
+// large cluster of nodes racing after the same event (e.g. an etcd
+// partition healing) from retrying in lockstep and thundering the herd
+// against the leader key.
+type BackoffConfig struct {
+	Base       time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// nextSleep returns the next backoff sleep duration given the previous
+// one (0 on the first call).
+func (b BackoffConfig) nextSleep(prev time.Duration) time.Duration {
+	mul := b.Multiplier
+	if mul <= 0 {
+		mul = 3
+	}
+	ceil := b.Base
+	if prev > 0 {
+		if scaled := time.Duration(float64(prev) * mul); scaled > ceil {
+			ceil = scaled
+		}
+	}
+	if ceil > b.Max {
+		ceil = b.Max
+	}
+	if ceil <= b.Base {
+		return b.Base
+	}
+	return b.Base + time.Duration(rand.Int63n(int64(ceil-b.Base)))
+}
+
+// OwnerManagerConfig holds the tunables for the election session TTL and
+// for the backoff used when (re)establishing a session or retrying a
+// failed campaign.
+//
+// These are meant to be tunable at runtime via tidb_config system
+// variables (see the TiDBDDLOwner* names below); that variable
+// registration lives in the sessionctx/variable package, which isn't
+// part of this tree, so SetConfig is the whole of the wiring here. A
+// variable's SetSession/SetGlobal hook would call SetConfig with an
+// OwnerManagerConfig built from the other current values plus the one
+// being changed.
+type OwnerManagerConfig struct {
+	SessionTTL        int
+	NewSessionRetry   int
+	NewSessionBackoff BackoffConfig
+	CampaignBackoff   BackoffConfig
+}
+
+// Names tidb_config system variables would use to tune OwnerManagerConfig
+// without a restart, matching the rest of tidb's "tidb_ddl_*" naming.
 const (
-	ddlOwnerKey               = "/tidb/ddl/owner"
-	bgOwnerKey                = "/tidb/ddl/bg/owner"
+	TiDBDDLOwnerSessionTTL      = "tidb_ddl_owner_session_ttl"
+	TiDBDDLOwnerNewSessionRetry = "tidb_ddl_owner_new_session_retry"
+)
+
+// DefaultOwnerManagerConfig returns the OwnerManagerConfig used when
+// NewOwnerManager isn't given one explicitly.
+func DefaultOwnerManagerConfig() OwnerManagerConfig {
+	return OwnerManagerConfig{
+		SessionTTL:      newSessionTTL,
+		NewSessionRetry: newSessionDefaultRetryCnt,
+		NewSessionBackoff: BackoffConfig{
+			Base: 200 * time.Millisecond,
+			Max:  3 * time.Second,
+		},
+		CampaignBackoff: BackoffConfig{
+			Base: 200 * time.Millisecond,
+			Max:  3 * time.Second,
+		},
+	}
+}
+
+// OwnerChangeListener is called by ownerManager whenever this node's
+// ownership of key changes. leaderID is the node that is the owner of key
+// after the change (it may be empty if that isn't known yet).
+type OwnerChangeListener func(key string, isOwner bool, leaderID string)
+
+// ownerChangeEvent is queued on ownerManager.notifyCh and fanned out to
+// subscribers by dispatchNotifications.
+type ownerChangeEvent struct {
+	key      string
+	isOwner  bool
+	leaderID string
+}
+
+// notifyChanSize bounds how many owner-change events can be queued before
+// dispatchNotifications catches up; beyond that, new events are dropped
+// rather than blocking the campaign loop.
+const notifyChanSize = 256
+
+const (
+	ddlOwnerKey = "/tidb/ddl/owner"
+	bgOwnerKey  = "/tidb/ddl/bg/owner"
+	// ownerTransferKey and ownerTransferAckKey are siblings of ddlOwnerKey,
+	// not children of it: ddlOwnerKey also doubles as the
+	// concurrency.Election prefix for the owner race itself, and
+	// Election.Campaign/Leader list by that prefix and pick by lowest
+	// create-revision, so a key nested under it (e.g. the old
+	// ddlOwnerKey+"/transfer") gets mistaken for a participant and wedges
+	// the election forever.
+	ownerTransferKey          = "/tidb/ddl/owner-transfer"
+	ownerTransferAckKey       = ownerTransferKey + "-ack"
 	newSessionDefaultRetryCnt = 3
 	newSessionRetryUnlimited  = math.MaxInt64
 )
 
+// priorityKey returns the key prefix candidates announce their candidacy
+// under (see priorityCandidateKey for each candidate's own sub-key under
+// it), so the current owner of key can notice a higher-priority candidate
+// and voluntarily yield to it. It's a sibling of key, not a child of it,
+// for the same reason ownerTransferKey is: key doubles as the
+// concurrency.Election prefix for the owner race, and a stray key under
+// it wedges Election.Campaign/Leader.
+func priorityKey(key string) string {
+	return key + "-candidates"
+}
+
+// priorityCandidateKey returns the key ddlID announces its own candidacy
+// on under priorityKey(key). Each candidate gets its own key, rather than
+// all of them sharing and overwriting a single key, so the owner can see
+// every still-campaigning candidate and find the true highest priority
+// instead of whichever one last overwrote the shared value.
+func priorityCandidateKey(key, ddlID string) string {
+	return priorityKey(key) + "/" + ddlID
+}
+
+// CandidateInfo is the exported view of a node's campaign announcement,
+// returned by OwnerManager.Candidates for a caller such as
+// INFORMATION_SCHEMA.TIDB_SERVERS_INFO to surface Labels and Priority
+// without reaching into driver-internal state.
+type CandidateInfo struct {
+	ID       string
+	Priority int
+	Labels   map[string]string
+}
+
+// OwnerPolicy configures how a node participates in key's campaigns.
+type OwnerPolicy struct {
+	// Priority makes this node preferred over lower-priority candidates:
+	// a lower-priority owner voluntarily resigns once it notices a
+	// higher-priority candidate campaigning, e.g. to prefer an owner in
+	// the primary DC over a cross-region replica after a partition.
+	Priority int
+	// ReadOnly nodes watch ownership but never campaign, so they can serve
+	// reads/DDL routing without ever becoming the owner.
+	ReadOnly bool
+	// Labels are carried in this node's campaign value for observers
+	// (e.g. INFORMATION_SCHEMA.TIDB_SERVERS_INFO) to display.
+	Labels map[string]string
+}
+
+// candidateInfo is the JSON value a node campaigns and announces with; it
+// carries enough information for other candidates and the current owner
+// to compare priorities.
+type candidateInfo struct {
+	ID       string            `json:"id"`
+	Priority int               `json:"priority"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+func encodeCandidate(id string, policy OwnerPolicy) (string, error) {
+	buf, err := json.Marshal(candidateInfo{ID: id, Priority: policy.Priority, Labels: policy.Labels})
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return string(buf), nil
+}
+
+func decodeCandidate(val string) (candidateInfo, error) {
+	var info candidateInfo
+	err := json.Unmarshal([]byte(val), &info)
+	return info, errors.Trace(err)
+}
+
+// newSessionTTL is the election session's TTL in seconds.
+const newSessionTTL = 10
+
 // ownerManager represents the structure which is used for electing owner.
+// It delegates the actual campaign/observe/resign primitives to an
+// ElectionDriver, so the same state machine works whether leadership is
+// coordinated through etcd or, for standalone deployments, entirely
+// in-process.
 type ownerManager struct {
 	ddlOwner    int32
 	bgOwner     int32
 	ddlID       string // id is the ID of DDL.
-	etcdCli     *clientv3.Client
-	etcdSession *concurrency.Session
+	policy      OwnerPolicy
+	driver      ElectionDriver
+	sessionDone <-chan struct{}
 	cancel      goctx.CancelFunc
+
+	listenersMu sync.RWMutex
+	listeners   []OwnerChangeListener
+	notifyCh    chan ownerChangeEvent
+
+	cfgMu sync.RWMutex
+	cfg   OwnerManagerConfig
 }
 
-// NewOwnerManager creates a new OwnerManager.
-func NewOwnerManager(etcdCli *clientv3.Client, id string, cancel goctx.CancelFunc) OwnerManager {
+// NewOwnerManager creates a new OwnerManager. When etcdCli is nil, it falls
+// back to a localDriver so a standalone TiDB started without an etcd
+// cluster can still run DDL and background jobs.
+func NewOwnerManager(etcdCli *clientv3.Client, id string, policy OwnerPolicy, cancel goctx.CancelFunc) OwnerManager {
+	var driver ElectionDriver
+	if etcdCli == nil {
+		driver = newLocalDriver(id)
+	} else {
+		driver = newEtcdDriver(etcdCli)
+	}
 	return &ownerManager{
-		etcdCli: etcdCli,
-		ddlID:   id,
-		cancel:  cancel,
+		driver:   driver,
+		ddlID:    id,
+		policy:   policy,
+		cancel:   cancel,
+		notifyCh: make(chan ownerChangeEvent, notifyChanSize),
+		cfg:      DefaultOwnerManagerConfig(),
+	}
+}
+
+// Config implements OwnerManager.Config interface.
+func (m *ownerManager) Config() OwnerManagerConfig {
+	m.cfgMu.RLock()
+	defer m.cfgMu.RUnlock()
+	return m.cfg
+}
+
+// SetConfig implements OwnerManager.SetConfig interface.
+func (m *ownerManager) SetConfig(cfg OwnerManagerConfig) {
+	m.cfgMu.Lock()
+	m.cfg = cfg
+	m.cfgMu.Unlock()
+}
+
+// Subscribe implements OwnerManager.Subscribe interface.
+func (m *ownerManager) Subscribe(listener OwnerChangeListener) {
+	m.listenersMu.Lock()
+	m.listeners = append(m.listeners, listener)
+	m.listenersMu.Unlock()
+}
+
+// notify queues an owner-change event for dispatchNotifications. The send
+// is non-blocking: if notifyCh is full, the event is dropped and counted
+// instead of stalling the campaign loop behind a slow subscriber.
+func (m *ownerManager) notify(key string, isOwner bool, leaderID string) {
+	select {
+	case m.notifyCh <- ownerChangeEvent{key: key, isOwner: isOwner, leaderID: leaderID}:
+	default:
+		ownerListenerDroppedCounter.Inc()
+		log.Warnf("[ddl] ownerManager %s dropped an owner-change notification for %s, a subscriber is too slow", m.ddlID, key)
+	}
+}
+
+// dispatchNotifications fans queued owner-change events out to subscribers,
+// decoupled from the producers by notifyCh. The read lock is held only to
+// take a snapshot of the listener list; listeners are called after it's
+// released.
+func (m *ownerManager) dispatchNotifications(ctx goctx.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case ev := <-m.notifyCh:
+			m.listenersMu.RLock()
+			listeners := m.listeners
+			m.listenersMu.RUnlock()
+			for _, listener := range listeners {
+				listener(ev.key, ev.isOwner, ev.leaderID)
+			}
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
@@ -109,49 +386,68 @@ func (m *ownerManager) SetBgOwner(isOwner bool) {
 	}
 }
 
-// newSessionTTL is the etcd session's TTL in seconds.
-const newSessionTTL = 10
-
+// newSession (re)establishes the election session, retrying up to retryCnt
+// times with a decorrelated-jitter backoff between attempts so that a
+// large cluster of nodes reconnecting after the same event (e.g. an etcd
+// partition healing) doesn't retry in lockstep.
 func (m *ownerManager) newSession(ctx goctx.Context, retryCnt int) error {
-	var err error
+	var (
+		err       error
+		prevSleep time.Duration
+	)
 	for i := 0; i < retryCnt; i++ {
-		m.etcdSession, err = concurrency.NewSession(m.etcdCli,
-			concurrency.WithTTL(newSessionTTL), concurrency.WithContext(ctx))
+		cfg := m.Config()
+		var done <-chan struct{}
+		done, err = m.driver.Session(ctx, cfg.SessionTTL)
 		if err == nil {
-			break
+			m.sessionDone = done
+			ownerSessionTTLGauge.Set(float64(cfg.SessionTTL))
+			return nil
 		}
 		log.Warnf("[ddl] failed to new session, err %v", err)
 		if isContextFinished(err) {
 			break
 		}
-		time.Sleep(200 * time.Millisecond)
-		continue
+		prevSleep = cfg.NewSessionBackoff.nextSleep(prevSleep)
+		time.Sleep(prevSleep)
 	}
 	return errors.Trace(err)
 }
 
 // CampaignOwners implements OwnerManager.CampaignOwners interface.
 func (m *ownerManager) CampaignOwners(ctx goctx.Context, wg *sync.WaitGroup) error {
-	err := m.newSession(ctx, newSessionDefaultRetryCnt)
+	err := m.newSession(ctx, m.Config().NewSessionRetry)
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	wg.Add(2)
+	wg.Add(4)
 	ddlCtx, _ := goctx.WithCancel(ctx)
 	go m.campaignLoop(ddlCtx, ddlOwnerKey, wg)
 
 	bgCtx, _ := goctx.WithCancel(ctx)
 	go m.campaignLoop(bgCtx, bgOwnerKey, wg)
+
+	transferCtx, _ := goctx.WithCancel(ctx)
+	go m.watchTransferIntent(transferCtx, wg)
+
+	notifyCtx, _ := goctx.WithCancel(ctx)
+	go m.dispatchNotifications(notifyCtx, wg)
 	return nil
 }
 
 func (m *ownerManager) campaignLoop(ctx goctx.Context, key string, wg *sync.WaitGroup) {
 	defer wg.Done()
+	if m.policy.ReadOnly {
+		m.observeOnlyLoop(ctx, key)
+		return
+	}
+
+	var prevCampaignSleep time.Duration
 	for {
 		select {
-		case <-m.etcdSession.Done():
-			log.Info("[ddl] etcd session is done, creates a new one")
+		case <-m.sessionDone:
+			log.Info("[ddl] election session is done, creates a new one")
 			err := m.newSession(ctx, newSessionRetryUnlimited)
 			if err != nil {
 				log.Infof("[ddl] break %s campaign loop, err %v", key, err)
@@ -163,35 +459,48 @@ func (m *ownerManager) campaignLoop(ctx goctx.Context, key string, wg *sync.Wait
 		default:
 		}
 
-		elec := concurrency.NewElection(m.etcdSession, key)
-		err := elec.Campaign(ctx, m.ddlID)
+		val, err := encodeCandidate(m.ddlID, m.policy)
 		if err != nil {
+			log.Warnf("[ddl] ownerManager %s failed to encode candidacy, err %v", m.ddlID, err)
+			return
+		}
+		if td, ok := m.driver.(transferableDriver); ok {
+			if err := td.Notify(ctx, priorityCandidateKey(key, m.ddlID), val); err != nil {
+				log.Warnf("[ddl] ownerManager %s failed to announce candidacy for %s, err %v", m.ddlID, key, err)
+			}
+		}
+
+		ownerCampaignCounter.WithLabelValues(key).Inc()
+		err = m.driver.Campaign(ctx, key, val)
+		if err != nil {
+			reason := "campaign_error"
+			if isContextFinished(err) {
+				reason = "context_done"
+			}
+			ownerCampaignFailedCounter.WithLabelValues(key, reason).Inc()
 			log.Infof("[ddl] %s ownerManager %s failed to campaign, err %v", key, m.ddlID, err)
 			if isContextFinished(err) {
 				log.Warnf("[ddl] break %s campaign loop, err %v", key, err)
 				return
 			}
+			prevCampaignSleep = m.Config().CampaignBackoff.nextSleep(prevCampaignSleep)
+			time.Sleep(prevCampaignSleep)
 			continue
 		}
+		prevCampaignSleep = 0
+		log.Infof("[ddl] %s ownerManager is %s, owner is %v", key, m.ddlID, m.ddlID)
+		m.setOwnerVal(key, true)
+		ownerIsOwnerGauge.WithLabelValues(key).Set(1)
+		m.notify(key, true, m.ddlID)
 
-		// Get owner information.
-		resp, err := elec.Leader(ctx)
-		if err != nil {
-			// If no leader elected currently, it returns ErrElectionNoLeader.
-			log.Infof("[ddl] failed to get leader, err %v", err)
-			continue
-		}
-		leader := string(resp.Kvs[0].Value)
-		log.Infof("[ddl] %s ownerManager is %s, owner is %v", key, m.ddlID, leader)
+		leader := m.watchOwner(ctx, key)
 		if leader == m.ddlID {
-			m.setOwnerVal(key, true)
-		} else {
-			log.Warnf("[ddl] ownerManager %s isn't the owner", m.ddlID)
-			continue
+			// watchOwner didn't observe who, if anyone, took over.
+			leader = ""
 		}
-
-		m.watchOwner(ctx, string(resp.Kvs[0].Key))
 		m.setOwnerVal(key, false)
+		ownerIsOwnerGauge.WithLabelValues(key).Set(0)
+		m.notify(key, false, leader)
 	}
 }
 
@@ -203,28 +512,284 @@ func (m *ownerManager) setOwnerVal(key string, val bool) {
 	}
 }
 
-func (m *ownerManager) watchOwner(ctx goctx.Context, key string) {
+// observeOnlyLoop keeps a ReadOnly node observing key's ownership without
+// ever entering the campaign. Each observed owner change is counted in
+// ownerWatchEventsCounter; the observed owner itself isn't recorded. When
+// there's no current owner, driver.Observe returns an already-closed
+// channel, so re-observing is backed off with the same jitter as a failed
+// campaign instead of busy-spinning until one is elected.
+func (m *ownerManager) observeOnlyLoop(ctx goctx.Context, key string) {
+	var prevSleep time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		observed := false
+		for range m.driver.Observe(ctx, key) {
+			observed = true
+			ownerWatchEventsCounter.WithLabelValues(key).Inc()
+		}
+		if observed {
+			prevSleep = 0
+			continue
+		}
+
+		prevSleep = m.Config().CampaignBackoff.nextSleep(prevSleep)
+		select {
+		case <-time.After(prevSleep):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchOwner blocks while this node remains the owner of key, returning the
+// last leader it observed once that's no longer true. While it's the
+// owner, it also watches priorityKey(key) for higher-priority candidates
+// and voluntarily resigns in their favor.
+func (m *ownerManager) watchOwner(ctx goctx.Context, key string) string {
 	log.Debugf("[ddl] ownerManager %s watch owner key %v", m.ddlID, key)
-	watchCh := m.etcdCli.Watch(ctx, key)
+	obs := m.driver.Observe(ctx, key)
+	leader := m.ddlID
+
+	td, ok := m.driver.(transferableDriver)
+	if !ok {
+		return m.watchOwnerLoop(ctx, key, leader, obs, nil)
+	}
+	announceCh := td.WatchPrefix(ctx, priorityKey(key))
+
+	// Each candidate announces itself once, under its own
+	// priorityCandidateKey, and then blocks in Campaign, so announceCh,
+	// which only delivers PUTs from here on, would never see an
+	// announcement that predates this watch. Scan every candidate key
+	// that's already there so an already-waiting higher-priority
+	// candidate isn't missed.
+	if candidates, err := td.GetPrefix(ctx, priorityKey(key)); err != nil {
+		log.Warnf("[ddl] ownerManager %s failed to read candidates of %s, err %v", m.ddlID, key, err)
+	} else if cand, ok := highestCandidate(candidates); ok && m.yieldIfOutranked(ctx, key, cand) {
+		return leader
+	}
+
+	return m.watchOwnerLoop(ctx, key, leader, obs, announceCh)
+}
+
+// Candidates implements OwnerManager.Candidates interface.
+func (m *ownerManager) Candidates(ctx goctx.Context, key string) ([]CandidateInfo, error) {
+	td, ok := m.driver.(transferableDriver)
+	if !ok {
+		return nil, nil
+	}
+	vals, err := td.GetPrefix(ctx, priorityKey(key))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	infos := make([]CandidateInfo, 0, len(vals))
+	for _, raw := range vals {
+		cand, err := decodeCandidate(raw)
+		if err != nil {
+			log.Warnf("[ddl] ownerManager %s failed to decode candidate of %s, err %v", m.ddlID, key, err)
+			continue
+		}
+		infos = append(infos, CandidateInfo{ID: cand.ID, Priority: cand.Priority, Labels: cand.Labels})
+	}
+	return infos, nil
+}
+
+// highestCandidate decodes raw per-candidate announcements (keyed by their
+// priorityCandidateKey, see GetPrefix/WatchPrefix) and returns the one with
+// the highest priority.
+func highestCandidate(vals map[string]string) (candidateInfo, bool) {
+	var best candidateInfo
+	found := false
+	for _, raw := range vals {
+		cand, err := decodeCandidate(raw)
+		if err != nil {
+			continue
+		}
+		if !found || cand.Priority > best.Priority {
+			best = cand
+			found = true
+		}
+	}
+	return best, found
+}
+
+// watchOwnerLoop is the common tail of watchOwner: it blocks on obs and
+// announceCh (nil if the driver doesn't support priority candidacy) until
+// this node loses ownership of key.
+func (m *ownerManager) watchOwnerLoop(ctx goctx.Context, key, leader string, obs <-chan string, announceCh <-chan string) string {
 	for {
 		select {
-		case resp := <-watchCh:
-			if resp.Canceled {
-				log.Infof("[ddl] ownerManager %s watch owner key %v failed, no owner",
-					m.ddlID, key)
-				return
+		case raw, ok := <-obs:
+			if !ok {
+				log.Infof("[ddl] ownerManager %s watch owner key %v failed, no owner", m.ddlID, key)
+				return leader
 			}
-
-			for _, ev := range resp.Events {
-				if ev.Type == mvccpb.DELETE {
-					log.Infof("[ddl] ownerManager %s watch owner key %v failed, owner is deleted", m.ddlID, key)
-					return
-				}
+			ownerWatchEventsCounter.WithLabelValues(key).Inc()
+			cand, err := decodeCandidate(raw)
+			if err != nil {
+				log.Warnf("[ddl] ownerManager %s failed to decode owner value of %s, err %v", m.ddlID, key, err)
+				continue
 			}
-		case <-m.etcdSession.Done():
-			return
+			leader = cand.ID
+			if leader != m.ddlID {
+				log.Infof("[ddl] ownerManager %s watch owner key %v failed, owner is %v", m.ddlID, key, leader)
+				return leader
+			}
+		case raw, ok := <-announceCh:
+			if !ok {
+				announceCh = nil
+				continue
+			}
+			cand, err := decodeCandidate(raw)
+			if err != nil {
+				log.Warnf("[ddl] ownerManager %s failed to decode candidate value of %s, err %v", m.ddlID, key, err)
+				continue
+			}
+			if m.yieldIfOutranked(ctx, key, cand) {
+				return leader
+			}
+		case <-m.sessionDone:
+			return leader
 		case <-ctx.Done():
-			return
+			return leader
+		}
+	}
+}
+
+// yieldIfOutranked resigns key's election if cand, a candidate announced
+// under priorityKey(key), is still campaigning and outranks this node, so
+// a preferred candidate doesn't wait out the current owner's full session
+// TTL.
+func (m *ownerManager) yieldIfOutranked(ctx goctx.Context, key string, cand candidateInfo) bool {
+	if cand.ID == m.ddlID || cand.Priority <= m.policy.Priority {
+		return false
+	}
+	log.Infof("[ddl] ownerManager %s yielding %s to higher-priority candidate %s", m.ddlID, key, cand.ID)
+	if err := m.driver.Resign(ctx, key); err != nil {
+		log.Warnf("[ddl] ownerManager %s failed to yield %s, err %v", m.ddlID, key, err)
+	}
+	return true
+}
+
+// ResignOwner implements OwnerManager.ResignOwner interface.
+func (m *ownerManager) ResignOwner(ctx goctx.Context) error {
+	return m.resign(ctx, ddlOwnerKey)
+}
+
+// ResignBgOwner implements OwnerManager.ResignBgOwner interface.
+func (m *ownerManager) ResignBgOwner(ctx goctx.Context) error {
+	return m.resign(ctx, bgOwnerKey)
+}
+
+// resign gives up key's election through the driver, which for etcdDriver
+// only deletes the election's own key (the session/lease backing it is
+// left intact). The campaignLoop goroutine observes the loss of
+// leadership through watchOwner and loops back to Campaign, so the node
+// re-enters the race instead of tearing down.
+func (m *ownerManager) resign(ctx goctx.Context, key string) error {
+	if err := m.driver.Resign(ctx, key); err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := m.driver.(*localDriver); ok {
+		// A standalone node stays the owner: its watchOwner never returns to
+		// campaign again, so clearing the flag here would never be undone.
+		return nil
+	}
+	m.setOwnerVal(key, false)
+	ownerIsOwnerGauge.WithLabelValues(key).Set(0)
+	m.notify(key, false, "")
+	log.Infof("[ddl] ownerManager %s resigned %s", m.ddlID, key)
+	return nil
+}
+
+// ownerTransferTimeout bounds how long TransferOwner waits for targetID to
+// acknowledge a transfer intent, so a dead or misnamed candidate can't hang
+// the caller indefinitely even when ctx itself has no deadline.
+const ownerTransferTimeout = 10 * time.Second
+
+// TransferOwner implements OwnerManager.TransferOwner interface. It writes
+// an intent key watched by every candidate, waits for the target to
+// acknowledge it, then resigns the DDL owner key, so DDL leadership moves
+// without waiting for the session TTL to expire. The ack only confirms the
+// target is alive: after the resign the key goes to whichever candidate
+// the driver's election picks next, which isn't necessarily targetID.
+//
+// This is the method an `ADMIN TRANSFER DDL OWNER TO '<id>'` statement is
+// meant to call through the admin executor; the parser grammar and
+// executor plumbing for that statement don't exist in this package and
+// aren't added here.
+func (m *ownerManager) TransferOwner(ctx goctx.Context, targetID string) error {
+	if !m.IsOwner() {
+		return errors.Errorf("ownerManager %s isn't the DDL owner, can't transfer to %s", m.ddlID, targetID)
+	}
+	td, ok := m.driver.(transferableDriver)
+	if !ok {
+		return errors.Errorf("ownerManager %s's ElectionDriver doesn't support owner transfer", m.ddlID)
+	}
+
+	waitCtx, cancel := goctx.WithTimeout(ctx, ownerTransferTimeout)
+	defer cancel()
+
+	// Start watching for the ack before publishing the intent: WatchKey
+	// only delivers values from the current point forward, so starting the
+	// watch after Notify could miss an ack written in between.
+	ackCh := td.WatchKey(waitCtx, ownerTransferAckKey)
+
+	if err := td.Notify(waitCtx, ownerTransferKey, targetID); err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		// Use ctx, not waitCtx, which may have already timed out: the
+		// intent should still be cleared so it doesn't linger and mislead
+		// the next TransferOwner's watchTransferIntent readers.
+		if err := td.Delete(ctx, ownerTransferKey); err != nil {
+			log.Warnf("[ddl] ownerManager %s failed to clear owner transfer intent, err %v", m.ddlID, err)
+		}
+	}()
+
+	if err := m.waitTransferAck(waitCtx, ackCh, targetID); err != nil {
+		return errors.Trace(err)
+	}
+	if err := td.Delete(ctx, ownerTransferAckKey); err != nil {
+		log.Warnf("[ddl] ownerManager %s failed to clear owner transfer ack, err %v", m.ddlID, err)
+	}
+
+	return errors.Trace(m.ResignOwner(ctx))
+}
+
+// waitTransferAck blocks until targetID acknowledges a transfer intent by
+// writing itself to ownerTransferAckKey, confirming it is alive and about
+// to campaign, or until ctx (bounded by ownerTransferTimeout in
+// TransferOwner) is done.
+func (m *ownerManager) waitTransferAck(ctx goctx.Context, ackCh <-chan string, targetID string) error {
+	for val := range ackCh {
+		if val == targetID {
+			return nil
+		}
+	}
+	return errors.Trace(ctx.Err())
+}
+
+// watchTransferIntent watches ownerTransferKey for a TransferOwner intent
+// naming this node. When it sees one, it acknowledges the intent so the
+// current owner can resign. It doesn't interrupt campaignLoop: a campaign
+// backoff sleep already in progress still runs to completion.
+func (m *ownerManager) watchTransferIntent(ctx goctx.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	td, ok := m.driver.(transferableDriver)
+	if !ok {
+		return
+	}
+	for val := range td.WatchKey(ctx, ownerTransferKey) {
+		if val != m.ddlID {
+			continue
+		}
+		if err := td.Notify(ctx, ownerTransferAckKey, m.ddlID); err != nil {
+			log.Warnf("[ddl] ownerManager %s failed to ack owner transfer, err %v", m.ddlID, err)
 		}
 	}
 }
@@ -0,0 +1,69 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	ownerCampaignCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ddl",
+			Name:      "owner_campaign_total",
+			Help:      "Counter of owner campaign attempts, by key.",
+		}, []string{"key"})
+
+	ownerCampaignFailedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ddl",
+			Name:      "owner_campaign_failed_total",
+			Help:      "Counter of failed owner campaign attempts, by key and reason.",
+		}, []string{"key", "reason"})
+
+	ownerWatchEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ddl",
+			Name:      "owner_watch_events_total",
+			Help:      "Counter of owner key watch events observed, by key.",
+		}, []string{"key"})
+
+	ownerSessionTTLGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "ddl",
+			Name:      "owner_session_ttl_seconds",
+			Help:      "The TTL in seconds of the current owner election session.",
+		})
+
+	ownerIsOwnerGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "ddl",
+			Name:      "is_owner",
+			Help:      "Whether this instance is the owner (1) of key or not (0).",
+		}, []string{"key"})
+
+	ownerListenerDroppedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "ddl",
+			Name:      "owner_listener_dropped_total",
+			Help:      "Counter of owner-change notifications dropped because a subscriber was too slow.",
+		})
+)
+
+func init() {
+	prometheus.MustRegister(ownerCampaignCounter)
+	prometheus.MustRegister(ownerCampaignFailedCounter)
+	prometheus.MustRegister(ownerWatchEventsCounter)
+	prometheus.MustRegister(ownerSessionTTLGauge)
+	prometheus.MustRegister(ownerIsOwnerGauge)
+	prometheus.MustRegister(ownerListenerDroppedCounter)
+}